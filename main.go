@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/cosmo-workspace/controller-testtools/pkg/charts"
-	"github.com/fatih/color"
+	"github.com/cosmo-workspace/controller-testtools/pkg/manifest"
+	"github.com/cosmo-workspace/controller-testtools/pkg/report"
+	"github.com/cosmo-workspace/controller-testtools/pkg/review"
 	"github.com/spf13/cobra"
 	"golang.org/x/exp/slog"
 	"golang.org/x/sync/errgroup"
@@ -30,8 +35,26 @@ type option struct {
 	Namespace      string
 	Chart          string
 	ValuesFile     string
+	Manifest       string
+	Reports        []string
 	Debug          bool
 	UpdateSnapshot bool
+	Interactive    bool
+	SkipCRDs       bool
+
+	// Repository chart resolution. These mirror the flag surface of
+	// `helm fetch`/`helm install` so a chart reference like
+	// `myrepo/mychart` or `oci://.../mychart` can be snapshotted without
+	// vendoring it into the repo.
+	ChartVersion string
+	Repo         string
+	Username     string
+	Password     string
+	CAFile       string
+	CertFile     string
+	KeyFile      string
+	Keyring      string
+	Devel        bool
 }
 
 func main() {
@@ -61,6 +84,17 @@ testSpec:
         - /data/COOKIE_BLOCKKEY
         - /data/COOKIE_HASHKEY
         - /data/COOKIE_SESSION_NAME
+    # nameGlob/nameRegex/all match more than one resource by name, and
+    # valueRegex/replacement control what gets redacted and what it becomes.
+    - kind: Secret
+      all: true
+      valueRegex: "^[A-Za-z0-9]+$"
+      jsonPath:
+        - /data/apiToken
+      replacement: "<RANDOM>"
+    # preset expands to one of the built-in matchers for things nearly every
+    # chart generates.
+    - preset: kubernetesCABundle
 
 # Others can be any your chart value.
 # ...
@@ -84,24 +118,45 @@ MIT 2023 jlandowner/helm-chartsnap
   # Snapshot all test cases:
   chartsnap -c YOUR_CHART -f YOUR_TEST_VALUES_FILES_DIRECTOY
   
-  # Set addtional args or flags for 'helm template' command:
-  chartsnap -c YOUR_CHART -f YOUR_TEST_VALUES_FILE -- --skip-tests`,
+  # Set addtional args or flags for 'helm template' command.
+  # Additional args require '--helm-path' since the embedded Helm SDK used
+  # by default has no command line to forward them to:
+  chartsnap -c YOUR_CHART -f YOUR_TEST_VALUES_FILE --helm-path helm -- --skip-tests
+
+  # Snapshot a chart from a Helm repository or OCI registry:
+  chartsnap -c myrepo/mychart --version 1.2.3
+  chartsnap -c oci://registry.example.com/charts/mychart --version 1.2.3
+
+  # Review mismatches resource-by-resource instead of all-or-nothing:
+  chartsnap -c YOUR_CHART -f YOUR_TEST_VALUES_FILE -i`,
 		Version: fmt.Sprintf("version=%s commit=%s date=%s", version, commit, date),
 		RunE:    run,
 	}
 	rootCmd.PersistentFlags().BoolVar(&o.Debug, "debug", false, "debug mode")
 	rootCmd.PersistentFlags().BoolVarP(&o.UpdateSnapshot, "update-snapshot", "u", false, "update snapshot mode")
-	rootCmd.PersistentFlags().StringVarP(&o.Chart, "chart", "c", "", "path to the chart directory. this flag is passed to 'helm template RELEASE_NAME CHART --values VALUES' as 'CHART'")
+	rootCmd.PersistentFlags().BoolVarP(&o.Interactive, "interactive", "i", false, "on mismatch, review a diff per resource and choose to accept/reject/skip/quit instead of failing all-or-nothing")
+	rootCmd.PersistentFlags().StringVarP(&o.Chart, "chart", "c", "", "path to the chart directory. this flag is passed to 'helm template RELEASE_NAME CHART --values VALUES' as 'CHART'. required unless '--manifest' is set")
 	if err := rootCmd.MarkPersistentFlagDirname("chart"); err != nil {
 		panic(err)
 	}
-	if err := rootCmd.MarkPersistentFlagRequired("chart"); err != nil {
-		panic(err)
-	}
+	rootCmd.PersistentFlags().StringVarP(&o.Manifest, "manifest", "m", "", "path to a chartsnap.yaml test-suite manifest describing many charts and test cases. if set, '--chart' and '--values' are ignored")
 	rootCmd.PersistentFlags().StringVar(&o.ReleaseName, "release-name", "testrelease", "release name. this flag is passed to 'helm template RELEASE_NAME CHART --values VALUES' as 'RELEASE_NAME'")
 	rootCmd.PersistentFlags().StringVar(&o.Namespace, "namespace", "testns", "namespace. this flag is passed to 'helm template RELEASE_NAME CHART --values VALUES --namespace NAMESPACE' as 'NAMESPACE'")
-	rootCmd.PersistentFlags().StringVar(&o.HelmPath, "helm-path", "helm", "path to the helm command")
+	rootCmd.PersistentFlags().StringVar(&o.HelmPath, "helm-path", "", "path to the helm command. if unset, chartsnap templates the chart in-process using the embedded Helm SDK instead of forking a 'helm' process")
 	rootCmd.PersistentFlags().StringVarP(&o.ValuesFile, "values", "f", "", "path to a test values file or directory. if directroy is set, all test files are tested. if empty, default values are used. this flag is passed to 'helm template RELEASE_NAME CHART --values VALUES' as 'VALUES'")
+	rootCmd.PersistentFlags().BoolVar(&o.SkipCRDs, "skip-crds", false, "skip rendering the chart's crds/ directory. only applies when templating via the embedded Helm SDK; ignored when '--helm-path' is set")
+
+	rootCmd.PersistentFlags().StringVar(&o.ChartVersion, "version", "", "specify a version constraint for the chart version to use when '--chart' is a chart reference or repository URL. this flag is passed to 'helm pull' as '--version'")
+	rootCmd.PersistentFlags().StringVar(&o.Repo, "repo", "", "chart repository url where to locate the requested chart")
+	rootCmd.PersistentFlags().StringVar(&o.Username, "username", "", "chart repository username where to locate the requested chart")
+	rootCmd.PersistentFlags().StringVar(&o.Password, "password", "", "chart repository password where to locate the requested chart")
+	rootCmd.PersistentFlags().StringVar(&o.CAFile, "ca-file", "", "verify certificates of HTTPS-enabled servers using this CA bundle")
+	rootCmd.PersistentFlags().StringVar(&o.CertFile, "cert-file", "", "identify HTTPS client using this SSL certificate file")
+	rootCmd.PersistentFlags().StringVar(&o.KeyFile, "key-file", "", "identify HTTPS client using this SSL key file")
+	rootCmd.PersistentFlags().StringVar(&o.Keyring, "keyring", "", "location of a public keyring used to verify provenance of the chart before pulling it")
+	rootCmd.PersistentFlags().BoolVar(&o.Devel, "devel", false, "use development versions, too, equivalent to version '>0.0.0-0'. if '--version' is set, this is ignored")
+
+	rootCmd.PersistentFlags().StringArrayVar(&o.Reports, "report", nil, "write a machine-readable report in addition to the default TTY banners, as 'format=path'. supported formats are 'junit' and 'json'. can be set multiple times")
 
 	if err := rootCmd.Execute(); err != nil {
 		slog.Error(err.Error())
@@ -119,73 +174,166 @@ func run(cmd *cobra.Command, args []string) error {
 		}(),
 	}))
 	log.Debug("options", printOptions(*o)...)
+	defer func() {
+		if err := charts.CleanupPulledCharts(); err != nil {
+			log.Warn(err.Error())
+		}
+	}()
 
-	if o.ValuesFile == "" {
-		values = []string{""}
+	var cmdOpts []charts.HelmTemplateCmdOptions
+	if o.Manifest != "" {
+		m, err := manifest.Load(o.Manifest)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+		cmdOpts = m.HelmTemplateCmdOptions()
 	} else {
-		if s, err := os.Stat(o.ValuesFile); os.IsNotExist(err) {
-			return fmt.Errorf("values file '%s' not found", o.ValuesFile)
-		} else if s.IsDir() {
-			// get all values files in the directory
-			files, err := os.ReadDir(o.ValuesFile)
-			if err != nil {
-				return fmt.Errorf("failed to read values file directory: %w", err)
-			}
-			values = make([]string, 0)
-			for _, f := range files {
-				// read only *.yaml
-				if !f.IsDir() && strings.HasSuffix(f.Name(), ".yaml") {
-					values = append(values, path.Join(o.ValuesFile, f.Name()))
+		if o.Chart == "" {
+			return fmt.Errorf("either '--chart' or '--manifest' must be set")
+		}
+
+		if o.ValuesFile == "" {
+			values = []string{""}
+		} else {
+			if s, err := os.Stat(o.ValuesFile); os.IsNotExist(err) {
+				return fmt.Errorf("values file '%s' not found", o.ValuesFile)
+			} else if s.IsDir() {
+				// get all values files in the directory
+				files, err := os.ReadDir(o.ValuesFile)
+				if err != nil {
+					return fmt.Errorf("failed to read values file directory: %w", err)
 				}
+				values = make([]string, 0)
+				for _, f := range files {
+					// read only *.yaml
+					if !f.IsDir() && strings.HasSuffix(f.Name(), ".yaml") {
+						values = append(values, path.Join(o.ValuesFile, f.Name()))
+					}
+				}
+			} else {
+				values = []string{o.ValuesFile}
 			}
-		} else {
-			values = []string{o.ValuesFile}
+		}
+
+		for _, v := range values {
+			cmdOpts = append(cmdOpts, charts.HelmTemplateCmdOptions{
+				HelmPath:       o.HelmPath,
+				ReleaseName:    o.ReleaseName,
+				Namespace:      o.Namespace,
+				Chart:          o.Chart,
+				ValuesFile:     v,
+				AdditionalArgs: args,
+				SkipCRDs:       o.SkipCRDs,
+				ChartPullOptions: charts.ChartPullOptions{
+					Version:  o.ChartVersion,
+					Repo:     o.Repo,
+					Username: o.Username,
+					Password: o.Password,
+					CAFile:   o.CAFile,
+					CertFile: o.CertFile,
+					KeyFile:  o.KeyFile,
+					Keyring:  o.Keyring,
+					Devel:    o.Devel,
+				},
+			})
 		}
 	}
 
-	eg, ctx := errgroup.WithContext(cmd.Context())
-	for _, v := range values {
-		ht := charts.HelmTemplateCmdOptions{
-			HelmPath:       o.HelmPath,
-			ReleaseName:    o.ReleaseName,
-			Namespace:      o.Namespace,
-			Chart:          o.Chart,
-			ValuesFile:     v,
-			AdditionalArgs: args,
+	reporters := []report.Reporter{report.NewTTYReporter()}
+	for _, spec := range o.Reports {
+		r, err := report.Parse(spec)
+		if err != nil {
+			return err
 		}
-		bannerPrintln("RUNS",
-			fmt.Sprintf("Snapshot testing chart=%s values=%s", ht.Chart, ht.ValuesFile), 0, color.BgBlue)
-		eg.Go(func() error {
-			if o.UpdateSnapshot {
-				err := os.Remove(charts.SnapshotFile(ht.Chart, ht.ValuesFile))
-				if err != nil && !os.IsNotExist(err) {
-					return fmt.Errorf("failed to replace snapshot file: %w", err)
+		reporters = append(reporters, r)
+	}
+	for _, r := range reporters {
+		r.Start()
+	}
+
+	var runErr error
+	if o.Interactive {
+		// --interactive prompts on stdin per mismatch, so cases run one at a
+		// time rather than fanned out over the errgroup.
+		ctx := cmd.Context()
+		for _, ht := range cmdOpts {
+			report.RunningPrintln(fmt.Sprintf("Snapshot testing chart=%s values=%s", ht.Chart, ht.ValuesFile))
+			if err := runCase(ctx, ht, reporters); err != nil {
+				if errors.Is(err, review.ErrQuit) {
+					runErr = err
+					break
 				}
+				runErr = err
 			}
-			matched, failureMessage, err := charts.Snap(ctx, ht)
-			if err != nil {
-				bannerPrintln("FAIL", fmt.Sprintf("%v chart=%s values=%s", err, ht.Chart, ht.ValuesFile), color.FgRed, color.BgRed)
-				return fmt.Errorf("failed to get snapshot: %w chart=%s values=%s", err, ht.Chart, ht.ValuesFile)
-			}
-			if !matched {
-				bannerPrintln("FAIL", failureMessage, color.FgRed, color.BgRed)
-				return fmt.Errorf("not match snapshot chart=%s values=%s", ht.Chart, ht.ValuesFile)
-			}
-			return nil
-		})
+		}
+	} else {
+		eg, ctx := errgroup.WithContext(cmd.Context())
+		for _, ht := range cmdOpts {
+			ht := ht
+			report.RunningPrintln(fmt.Sprintf("Snapshot testing chart=%s values=%s", ht.Chart, ht.ValuesFile))
+			eg.Go(func() error {
+				return runCase(ctx, ht, reporters)
+			})
+		}
+		runErr = eg.Wait()
 	}
 
-	if err := eg.Wait(); err != nil {
-		return err
+	for _, r := range reporters {
+		if err := r.Finish(); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
 	}
-	bannerPrintln("PASS", "Snapshot matched", color.FgGreen, color.BgGreen)
 
-	return nil
+	return runErr
 }
 
-func bannerPrintln(banner string, message string, fgColor color.Attribute, bgColor color.Attribute) {
-	color.New(color.FgWhite, bgColor).Printf(" %s ", banner)
-	color.New(fgColor).Printf(" %s\n", message)
+// runCase templates and snapshots a single chart/values-file combination,
+// reviewing the result interactively when o.Interactive is set, and reports
+// the outcome to reporters.
+func runCase(ctx context.Context, ht charts.HelmTemplateCmdOptions, reporters []report.Reporter) error {
+	start := time.Now()
+	reportCase := func(status report.Status, failureMessage string) {
+		for _, r := range reporters {
+			r.Case(ht.Chart, ht.ValuesFile, status, failureMessage, time.Since(start))
+		}
+	}
+
+	if o.UpdateSnapshot {
+		err := os.Remove(charts.SnapshotFile(ht.Chart, ht.ValuesFile, ht.ReleaseName))
+		if err != nil && !os.IsNotExist(err) {
+			reportCase(report.Error, fmt.Sprintf("failed to replace snapshot file: %v", err))
+			return fmt.Errorf("failed to replace snapshot file: %w", err)
+		}
+	}
+
+	result, err := charts.Snap(ctx, ht)
+	if err != nil {
+		reportCase(report.Error, fmt.Sprintf("%v chart=%s values=%s", err, ht.Chart, ht.ValuesFile))
+		return fmt.Errorf("failed to get snapshot: %w chart=%s values=%s", err, ht.Chart, ht.ValuesFile)
+	}
+
+	if !result.Matched && o.Interactive {
+		if !review.Available() {
+			reportCase(report.Error, "--interactive requires a terminal on stdout")
+			return fmt.Errorf("--interactive requires a terminal on stdout")
+		}
+		matched, err := review.Run(result)
+		if err != nil {
+			reportCase(report.Error, err.Error())
+			return err
+		}
+		result.Matched = matched
+		if !matched {
+			result.FailureMessage = fmt.Sprintf("snapshot mismatch after review chart=%s values=%s", ht.Chart, ht.ValuesFile)
+		}
+	}
+
+	if !result.Matched {
+		reportCase(report.Fail, result.FailureMessage)
+		return fmt.Errorf("not match snapshot chart=%s values=%s", ht.Chart, ht.ValuesFile)
+	}
+	reportCase(report.Pass, "")
+	return nil
 }
 
 func printOptions(o option) []any {
@@ -0,0 +1,200 @@
+// Package charts runs `helm template` for a chart and a values file and
+// compares the rendered manifests against a stored snapshot.
+package charts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cosmo-workspace/controller-testtools/pkg/testcase"
+	"github.com/cosmo-workspace/controller-testtools/pkg/unstructured"
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+// snapshotDir is the directory name created next to a test values file to
+// hold its snapshot.
+const snapshotDir = "__snapshot__"
+
+// HelmTemplateCmdOptions holds everything needed to run `helm template` for
+// a single chart + values file combination.
+type HelmTemplateCmdOptions struct {
+	HelmPath    string
+	ReleaseName string
+	Namespace   string
+	Chart       string
+	ValuesFile  string
+	// AdditionalArgs are extra 'helm template' flags. They only apply when
+	// HelmPath is set and templating falls back to exec; the embedded SDK
+	// path has no subprocess command line to forward them to.
+	AdditionalArgs []string
+	// SkipCRDs disables rendering of the chart's crds/ directory when
+	// templating via the embedded SDK.
+	SkipCRDs bool
+	// DynamicFields are additional dynamicFields to redact on top of
+	// whatever the values file's own testSpec.dynamicFields declares, e.g.
+	// a manifest's top-level `defaults.dynamicFields` or a manifest chart
+	// entry's `testSpec.dynamicFields`.
+	DynamicFields []testcase.DynamicField
+
+	// ChartPullOptions configures how Chart is resolved when it is a
+	// reference to a Helm repository or OCI registry instead of a local
+	// directory.
+	ChartPullOptions ChartPullOptions
+}
+
+// SnapshotFile returns the path of the snapshot file for the given chart and
+// values file, e.g. `testdata/__snapshot__/values.yaml.snap`. releaseName is
+// only used as a fallback key when chart is a repository/OCI reference (so
+// there is no local chart directory to nest the snapshot under) and
+// valuesFile is empty.
+func SnapshotFile(chart, valuesFile, releaseName string) string {
+	if valuesFile == "" {
+		if isRemoteChart(chart) {
+			return filepath.Join(snapshotDir, releaseName+".snap")
+		}
+		return filepath.Join(chart, snapshotDir, "default.snap")
+	}
+	dir, file := filepath.Split(valuesFile)
+	return filepath.Join(dir, snapshotDir, file+".snap")
+}
+
+// Result is the outcome of a single Snap call. Rendered and Stored are
+// exposed (rather than just a pass/fail verdict) so a caller such as the
+// --interactive review mode can compute its own diff and selectively
+// rewrite the snapshot.
+type Result struct {
+	Matched        bool
+	FailureMessage string
+	// Rendered is the redacted manifests Snap just templated.
+	Rendered string
+	// Stored is the previous snapshot content. Empty when IsNewSnapshot.
+	Stored string
+	// IsNewSnapshot is true when there was no prior snapshot file; Snap
+	// always writes one in that case, the same as Jest creating a new
+	// snapshot on first run.
+	IsNewSnapshot bool
+	SnapshotFile  string
+}
+
+// Snap runs `helm template` with o and compares the rendered output against
+// the stored snapshot.
+func Snap(ctx context.Context, o HelmTemplateCmdOptions) (*Result, error) {
+	originalChart := o.Chart
+	chart, err := resolveChart(ctx, helmBinaryOrDefault(o.HelmPath), o.Chart, o.ChartPullOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve chart: %w", err)
+	}
+	o.Chart = chart
+
+	rendered, err := templateChart(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := loadTestSpec(o.ValuesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load testSpec: %w", err)
+	}
+	dynamicFields := append(append([]testcase.DynamicField{}, o.DynamicFields...), spec.DynamicFields...)
+	rendered, err = redact(rendered, dynamicFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact dynamic fields: %w", err)
+	}
+
+	snapshotFile := SnapshotFile(originalChart, o.ValuesFile, o.ReleaseName)
+	stored, err := os.ReadFile(snapshotFile)
+	if os.IsNotExist(err) {
+		if err := writeSnapshot(snapshotFile, rendered); err != nil {
+			return nil, fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		return &Result{Matched: true, Rendered: rendered, IsNewSnapshot: true, SnapshotFile: snapshotFile}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	result := &Result{Rendered: rendered, Stored: string(stored), SnapshotFile: snapshotFile}
+	if diff := cmp.Diff(string(stored), rendered); diff != "" {
+		result.FailureMessage = fmt.Sprintf("snapshot mismatch chart=%s values=%s\n%s", o.Chart, o.ValuesFile, diff)
+		return result, nil
+	}
+	result.Matched = true
+	return result, nil
+}
+
+// WriteSnapshot overwrites the snapshot file for a Result with content,
+// e.g. after an --interactive review accepts some of its hunks.
+func WriteSnapshot(result *Result, content string) error {
+	return writeSnapshot(result.SnapshotFile, content)
+}
+
+// templateChart renders o.Chart into a stream of Kubernetes manifests. When
+// o.HelmPath is unset it uses the embedded Helm SDK (templateChartSDK);
+// otherwise it forks the given 'helm' binary so a custom/pinned Helm
+// installation or a plugin-backed build can still be used.
+func templateChart(ctx context.Context, o HelmTemplateCmdOptions) (string, error) {
+	if o.HelmPath == "" {
+		if len(o.AdditionalArgs) > 0 {
+			return "", fmt.Errorf("additional 'helm template' args %v are not supported by the embedded Helm SDK; set --helm-path to template via exec instead", o.AdditionalArgs)
+		}
+		return templateChartSDK(o)
+	}
+	return templateChartExec(ctx, o)
+}
+
+func templateChartExec(ctx context.Context, o HelmTemplateCmdOptions) (string, error) {
+	args := []string{"template", o.ReleaseName, o.Chart, "--namespace", o.Namespace}
+	if o.ValuesFile != "" {
+		args = append(args, "--values", o.ValuesFile)
+	}
+	args = append(args, o.AdditionalArgs...)
+
+	cmd := exec.CommandContext(ctx, o.HelmPath, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", unstructured.NewUnknownError(stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// helmBinaryOrDefault returns the 'helm' binary to use for operations that
+// have no in-process equivalent yet (such as pulling a chart from a
+// repository), falling back to the 'helm' found on PATH when the user didn't
+// pin a binary with --helm-path.
+func helmBinaryOrDefault(helmPath string) string {
+	if helmPath == "" {
+		return "helm"
+	}
+	return helmPath
+}
+
+func loadTestSpec(valuesFile string) (*testcase.TestSpec, error) {
+	spec := &testcase.TestSpec{}
+	if valuesFile == "" {
+		return spec, nil
+	}
+	b, err := os.ReadFile(valuesFile)
+	if err != nil {
+		return nil, err
+	}
+	var values struct {
+		TestSpec testcase.TestSpec `yaml:"testSpec"`
+	}
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	return &values.TestSpec, nil
+}
+
+func writeSnapshot(path, rendered string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(rendered), 0o644)
+}
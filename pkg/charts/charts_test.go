@@ -0,0 +1,32 @@
+package charts
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotFileLocalChart(t *testing.T) {
+	got := SnapshotFile("./mychart", "", "testrelease")
+	want := filepath.Join("mychart", snapshotDir, "default.snap")
+	if got != want {
+		t.Errorf("SnapshotFile() = %q, want %q", got, want)
+	}
+}
+
+func TestSnapshotFileValuesFile(t *testing.T) {
+	got := SnapshotFile("./mychart", "testdata/values.yaml", "testrelease")
+	want := filepath.Join("testdata", snapshotDir, "values.yaml.snap")
+	if got != want {
+		t.Errorf("SnapshotFile() = %q, want %q", got, want)
+	}
+}
+
+func TestSnapshotFileRemoteChartFallsBackToReleaseName(t *testing.T) {
+	for _, chart := range []string{"myrepo/mychart", "oci://registry.example.com/charts/mychart"} {
+		got := SnapshotFile(chart, "", "testrelease")
+		want := filepath.Join(snapshotDir, "testrelease.snap")
+		if got != want {
+			t.Errorf("SnapshotFile(%q) = %q, want %q", chart, got, want)
+		}
+	}
+}
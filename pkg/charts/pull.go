@@ -0,0 +1,162 @@
+package charts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ChartPullOptions mirrors the flag surface `helm fetch`/`helm install`
+// exposes for resolving a chart reference (e.g. `myrepo/mychart` or
+// `oci://registry/mychart`) from a Helm repository or OCI registry instead
+// of a local directory.
+type ChartPullOptions struct {
+	Version  string
+	Repo     string
+	Username string
+	Password string
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	Keyring  string
+	Devel    bool
+}
+
+// isRemoteChart reports whether chart looks like a chart reference or OCI
+// registry URL rather than a path to a local chart directory.
+func isRemoteChart(chart string) bool {
+	if strings.HasPrefix(chart, "oci://") {
+		return true
+	}
+	if _, err := os.Stat(chart); err == nil {
+		return false
+	}
+	// `repo/chart` references a chart in a repository added via `helm repo add`.
+	return strings.Contains(chart, "/") && !strings.HasPrefix(chart, ".") && !strings.HasPrefix(chart, "/")
+}
+
+// pulled caches one `helm pull` per distinct (chart, version, repo, ...)
+// so a chart is only fetched once even though Snap (and therefore
+// resolveChart) runs once per values file. The temp directories it creates
+// are removed by CleanupPulledCharts.
+var pulled = struct {
+	mu    sync.Mutex
+	byKey map[string]*pullResult
+	dirs  []string
+}{byKey: map[string]*pullResult{}}
+
+type pullResult struct {
+	once sync.Once
+	dir  string
+	err  error
+}
+
+// resolveChart pulls chart into a temporary directory using `helm pull` when
+// it is a chart reference or OCI registry URL, returning the local path to
+// the extracted chart. Local chart directories are returned unchanged.
+func resolveChart(ctx context.Context, helmPath, chart string, o ChartPullOptions) (string, error) {
+	if !isRemoteChart(chart) {
+		return chart, nil
+	}
+
+	key := strings.Join([]string{chart, o.Version, o.Repo, fmt.Sprint(o.Devel)}, "\x00")
+
+	pulled.mu.Lock()
+	pr, ok := pulled.byKey[key]
+	if !ok {
+		pr = &pullResult{}
+		pulled.byKey[key] = pr
+	}
+	pulled.mu.Unlock()
+
+	pr.once.Do(func() {
+		pr.dir, pr.err = pullChart(ctx, helmPath, chart, o)
+	})
+	return pr.dir, pr.err
+}
+
+func pullChart(ctx context.Context, helmPath, chart string, o ChartPullOptions) (string, error) {
+	destDir, err := os.MkdirTemp("", "chartsnap-chart-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir for pulled chart: %w", err)
+	}
+	pulled.mu.Lock()
+	pulled.dirs = append(pulled.dirs, destDir)
+	pulled.mu.Unlock()
+
+	args := []string{"pull", chart, "--destination", destDir, "--untar"}
+	if o.Version != "" {
+		args = append(args, "--version", o.Version)
+	} else if o.Devel {
+		args = append(args, "--devel")
+	}
+	if o.Repo != "" {
+		args = append(args, "--repo", o.Repo)
+	}
+	if o.Username != "" {
+		args = append(args, "--username", o.Username)
+	}
+	if o.Password != "" {
+		args = append(args, "--password", o.Password)
+	}
+	if o.CAFile != "" {
+		args = append(args, "--ca-file", o.CAFile)
+	}
+	if o.CertFile != "" {
+		args = append(args, "--cert-file", o.CertFile)
+	}
+	if o.KeyFile != "" {
+		args = append(args, "--key-file", o.KeyFile)
+	}
+	if o.Keyring != "" {
+		args = append(args, "--keyring", o.Keyring, "--verify")
+	}
+
+	cmd := exec.CommandContext(ctx, helmPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to pull chart '%s': %w\n%s", chart, err, stderr.String())
+	}
+
+	return chartDirInside(destDir)
+}
+
+// chartDirInside returns the single chart directory `helm pull --untar`
+// extracted into dir.
+func chartDirInside(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pulled chart directory: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			return dir + "/" + e.Name(), nil
+		}
+	}
+	return "", fmt.Errorf("no chart directory found in '%s' after pulling", dir)
+}
+
+// CleanupPulledCharts removes every temp directory resolveChart created for
+// a remote chart reference during this process's lifetime. Callers should
+// defer it once after all Snap calls have finished.
+func CleanupPulledCharts() error {
+	pulled.mu.Lock()
+	dirs := pulled.dirs
+	pulled.dirs = nil
+	pulled.byKey = map[string]*pullResult{}
+	pulled.mu.Unlock()
+
+	var errs []error
+	for _, dir := range dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove '%s': %w", dir, err))
+		}
+	}
+	return errors.Join(errs...)
+}
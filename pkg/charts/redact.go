@@ -0,0 +1,220 @@
+package charts
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/cosmo-workspace/controller-testtools/pkg/testcase"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultReplacement = "<REDACTED>"
+
+// redact replaces dynamic fields matched by fields (e.g. randAlphaNum
+// Secret data, generated CA bundles, Helm hook hashes) across every
+// resource in rendered with a stable placeholder, so re-running
+// `helm template` doesn't flap the snapshot on values Helm generates
+// itself.
+func redact(rendered string, fields []testcase.DynamicField) (string, error) {
+	if len(fields) == 0 {
+		return rendered, nil
+	}
+
+	resolved := make([]testcase.DynamicField, 0, len(fields))
+	for _, f := range fields {
+		rf, ok := testcase.ResolvePreset(f)
+		if !ok {
+			return "", fmt.Errorf("unknown dynamicFields preset '%s'", f.Preset)
+		}
+		resolved = append(resolved, rf)
+	}
+
+	docs := strings.Split(rendered, "\n---\n")
+	for i, doc := range docs {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		red, err := redactDocument(doc, resolved)
+		if err != nil {
+			return "", err
+		}
+		docs[i] = red
+	}
+	return strings.Join(docs, "\n---\n"), nil
+}
+
+func redactDocument(doc string, fields []testcase.DynamicField) (string, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &node); err != nil {
+		// Not parseable YAML (e.g. a helm source comment on its own or the
+		// unstructured.UnknownError block); leave it untouched.
+		return doc, nil
+	}
+	if len(node.Content) == 0 {
+		return doc, nil
+	}
+	root := node.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return doc, nil
+	}
+
+	apiVersion := scalarValue(root, "apiVersion")
+	kind := scalarValue(root, "kind")
+	var name, namespace string
+	if metadata := mapValue(root, "metadata"); metadata != nil {
+		name = scalarValue(metadata, "name")
+		namespace = scalarValue(metadata, "namespace")
+	}
+
+	for _, f := range fields {
+		matched, err := matchesResource(f, apiVersion, kind, name, namespace)
+		if err != nil {
+			return "", err
+		}
+		if !matched {
+			continue
+		}
+		for _, jsonPath := range f.JSONPath {
+			if err := redactPath(root, splitJSONPointer(jsonPath), f); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", fmt.Errorf("failed to re-marshal redacted resource: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func matchesResource(f testcase.DynamicField, apiVersion, kind, name, namespace string) (bool, error) {
+	if f.APIVersion != "" && f.APIVersion != apiVersion {
+		return false, nil
+	}
+	if f.Kind != "" && f.Kind != kind {
+		return false, nil
+	}
+	if f.Namespace != "" && f.Namespace != namespace {
+		return false, nil
+	}
+	if f.NamespaceRegex != "" {
+		re, err := regexp.Compile(f.NamespaceRegex)
+		if err != nil {
+			return false, fmt.Errorf("invalid namespaceRegex '%s': %w", f.NamespaceRegex, err)
+		}
+		if !re.MatchString(namespace) {
+			return false, nil
+		}
+	}
+	if f.NamespaceGlob != "" {
+		ok, err := path.Match(f.NamespaceGlob, namespace)
+		if err != nil {
+			return false, fmt.Errorf("invalid namespaceGlob '%s': %w", f.NamespaceGlob, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	switch {
+	case f.All:
+		return true, nil
+	case f.Name != "":
+		return f.Name == name, nil
+	case f.NameRegex != "":
+		re, err := regexp.Compile(f.NameRegex)
+		if err != nil {
+			return false, fmt.Errorf("invalid nameRegex '%s': %w", f.NameRegex, err)
+		}
+		return re.MatchString(name), nil
+	case f.NameGlob != "":
+		ok, err := path.Match(f.NameGlob, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid nameGlob '%s': %w", f.NameGlob, err)
+		}
+		return ok, nil
+	default:
+		return false, nil
+	}
+}
+
+// redactPath walks jsonPath (already split and JSON-pointer-unescaped) from
+// root and, if found and f.ValueRegex (if set) matches its current value,
+// overwrites it with f.Replacement.
+func redactPath(root *yaml.Node, jsonPath []string, f testcase.DynamicField) error {
+	if len(jsonPath) == 0 {
+		return nil
+	}
+	cur := root
+	for _, key := range jsonPath[:len(jsonPath)-1] {
+		cur = mapValue(cur, key)
+		if cur == nil {
+			return nil
+		}
+	}
+	leaf := jsonPath[len(jsonPath)-1]
+	valueNode := mapValue(cur, leaf)
+	if valueNode == nil {
+		return nil
+	}
+
+	if f.ValueRegex != "" {
+		re, err := regexp.Compile(f.ValueRegex)
+		if err != nil {
+			return fmt.Errorf("invalid valueRegex '%s': %w", f.ValueRegex, err)
+		}
+		if !re.MatchString(valueNode.Value) {
+			return nil
+		}
+	}
+
+	replacement := f.Replacement
+	if replacement == "" {
+		replacement = defaultReplacement
+	}
+	valueNode.Value = replacement
+	valueNode.Tag = "!!str"
+	return nil
+}
+
+// mapValue returns the value node for key in mapping node m, or nil if m
+// isn't a mapping or doesn't contain key.
+func mapValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the string value of key in mapping node m, or "" if
+// absent or not a scalar.
+func scalarValue(m *yaml.Node, key string) string {
+	v := mapValue(m, key)
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return v.Value
+}
+
+// splitJSONPointer splits a JSON-pointer-style path such as
+// '/metadata/annotations/helm.sh~1hook-hash' into its unescaped segments.
+func splitJSONPointer(p string) []string {
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil
+	}
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, "~1", "/")
+		part = strings.ReplaceAll(part, "~0", "~")
+		parts[i] = part
+	}
+	return parts
+}
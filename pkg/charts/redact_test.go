@@ -0,0 +1,53 @@
+package charts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cosmo-workspace/controller-testtools/pkg/testcase"
+)
+
+func TestRedactReplacesMatchedField(t *testing.T) {
+	rendered := `apiVersion: v1
+kind: Secret
+metadata:
+  name: myapp
+data:
+  token: abc123
+`
+	fields := []testcase.DynamicField{{
+		Kind:     "Secret",
+		Name:     "myapp",
+		JSONPath: []string{"/data/token"},
+	}}
+
+	out, err := redact(rendered, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "abc123") {
+		t.Errorf("redact() left the dynamic value in place:\n%s", out)
+	}
+	if !strings.Contains(out, defaultReplacement) {
+		t.Errorf("redact() did not substitute the default replacement:\n%s", out)
+	}
+}
+
+func TestRedactLeavesUnmatchedFieldsAlone(t *testing.T) {
+	rendered := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: myapp
+data:
+  token: abc123
+`
+	fields := []testcase.DynamicField{{Kind: "Secret", All: true, JSONPath: []string{"/data/token"}}}
+
+	out, err := redact(rendered, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "abc123") {
+		t.Errorf("redact() touched a resource that doesn't match the field's Kind:\n%s", out)
+	}
+}
@@ -0,0 +1,64 @@
+package charts
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+// templateChartSDK renders o.Chart in-process using the Helm v3 SDK instead
+// of forking a 'helm template' process. This avoids per-test process fork
+// overhead when the errgroup in main.go fans out over dozens of values
+// files, and it surfaces structured errors instead of stderr that has to be
+// scraped into an unstructured.UnknownError.
+func templateChartSDK(o HelmTemplateCmdOptions) (string, error) {
+	actionConfig := new(action.Configuration)
+	if err := actionConfig.Init(cli.New().RESTClientGetter(), o.Namespace, "memory", func(string, ...interface{}) {}); err != nil {
+		return "", fmt.Errorf("failed to initialize helm action configuration: %w", err)
+	}
+
+	chrt, err := loader.Load(o.Chart)
+	if err != nil {
+		return "", fmt.Errorf("failed to load chart '%s': %w", o.Chart, err)
+	}
+
+	values, err := loadValues(o.ValuesFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to load values '%s': %w", o.ValuesFile, err)
+	}
+
+	install := action.NewInstall(actionConfig)
+	install.DryRun = true
+	install.ClientOnly = true
+	install.Replace = true
+	install.ReleaseName = o.ReleaseName
+	install.Namespace = o.Namespace
+	install.IncludeCRDs = !o.SkipCRDs
+
+	rel, err := install.Run(chrt, values)
+	if err != nil {
+		return "", fmt.Errorf("failed to render chart '%s': %w", o.Chart, err)
+	}
+
+	manifests := []string{rel.Manifest}
+	for _, hook := range rel.Hooks {
+		manifests = append(manifests, hook.Manifest)
+	}
+	return strings.Join(manifests, "\n---\n"), nil
+}
+
+func loadValues(valuesFile string) (map[string]interface{}, error) {
+	base := map[string]interface{}{}
+	if valuesFile == "" {
+		return base, nil
+	}
+	override, err := chartutil.ReadValuesFile(valuesFile)
+	if err != nil {
+		return nil, err
+	}
+	return chartutil.CoalesceTables(override, base), nil
+}
@@ -0,0 +1,142 @@
+// Package diff computes per-resource hunks between two renderings of a
+// chart's manifests, using the same YAML document splitting the snapshotter
+// uses, so --interactive review can accept or reject one resource at a time
+// instead of all-or-nothing.
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// Hunk is the change (or addition/removal) of a single resource between an
+// old and a new rendering.
+type Hunk struct {
+	// Key identifies the resource as "apiVersion/kind namespace/name".
+	Key string
+	// OldText is empty when the resource is new.
+	OldText string
+	// NewText is empty when the resource was removed.
+	NewText string
+	// Unified is a human readable unified diff of OldText/NewText.
+	Unified string
+}
+
+// Hunks returns one Hunk per resource that differs (or was added/removed)
+// between oldRendered and newRendered. Resources identical in both are
+// omitted.
+func Hunks(oldRendered, newRendered string) []Hunk {
+	oldDocs := splitDocs(oldRendered)
+	newDocs := splitDocs(newRendered)
+	oldByKey := indexByKey(oldDocs)
+
+	var hunks []Hunk
+	seen := make(map[string]bool, len(newDocs))
+	for _, d := range newDocs {
+		seen[d.key] = true
+		old := oldByKey[d.key]
+		if old == d.text {
+			continue
+		}
+		hunks = append(hunks, Hunk{Key: d.key, OldText: old, NewText: d.text, Unified: unified(d.key, old, d.text)})
+	}
+	for _, d := range oldDocs {
+		if seen[d.key] {
+			continue
+		}
+		hunks = append(hunks, Hunk{Key: d.key, OldText: d.text, NewText: "", Unified: unified(d.key, d.text, "")})
+	}
+	return hunks
+}
+
+// Rebuild reconstructs a snapshot from newRendered, keeping OldText for any
+// hunk whose Key is not set to true in accepted (i.e. rejected or skipped),
+// and dropping resources whose removal (NewText == "") was accepted.
+func Rebuild(oldRendered, newRendered string, accepted map[string]bool) string {
+	oldDocs := splitDocs(oldRendered)
+	newDocs := splitDocs(newRendered)
+	oldByKey := indexByKey(oldDocs)
+
+	var out []string
+	seen := make(map[string]bool, len(newDocs))
+	for _, d := range newDocs {
+		seen[d.key] = true
+		old, hadOld := oldByKey[d.key]
+		switch {
+		case hadOld && old == d.text:
+			out = append(out, d.text)
+		case accepted[d.key]:
+			out = append(out, d.text)
+		case hadOld:
+			out = append(out, old)
+		// else: a rejected addition has nothing to fall back to, so it's
+		// simply left out of the snapshot and will show up again next run.
+		}
+	}
+	for _, d := range oldDocs {
+		if seen[d.key] {
+			continue
+		}
+		if accepted[d.key] {
+			continue // accepted removal
+		}
+		out = append(out, d.text)
+	}
+	return strings.Join(out, "\n---\n")
+}
+
+type doc struct {
+	key  string
+	text string
+}
+
+type resourceMeta struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+}
+
+func splitDocs(rendered string) []doc {
+	var docs []doc
+	for _, text := range strings.Split(rendered, "\n---\n") {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		var m resourceMeta
+		key := text
+		if err := yaml.Unmarshal([]byte(text), &m); err == nil {
+			key = fmt.Sprintf("%s/%s %s/%s", m.APIVersion, m.Kind, m.Metadata.Namespace, m.Metadata.Name)
+		}
+		docs = append(docs, doc{key: key, text: text})
+	}
+	return docs
+}
+
+func indexByKey(docs []doc) map[string]string {
+	m := make(map[string]string, len(docs))
+	for _, d := range docs {
+		m[d.key] = d.text
+	}
+	return m
+}
+
+func unified(key, old, new string) string {
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(old),
+		B:        difflib.SplitLines(new),
+		FromFile: key + " (snapshot)",
+		ToFile:   key + " (rendered)",
+		Context:  3,
+	}
+	text, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return fmt.Sprintf("failed to compute diff for %s: %v", key, err)
+	}
+	return text
+}
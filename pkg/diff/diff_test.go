@@ -0,0 +1,97 @@
+package diff
+
+import "testing"
+
+const configMapA = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: default
+data:
+  foo: bar
+`
+
+const configMapAChanged = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: default
+data:
+  foo: baz
+`
+
+const configMapB = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  namespace: default
+data:
+  foo: bar
+`
+
+func TestHunksOnlyReturnsChangedAddedRemoved(t *testing.T) {
+	old := configMapA + "\n---\n" + configMapB
+	new := configMapAChanged + "\n---\n" + configMapB
+
+	hunks := Hunks(old, new)
+	if len(hunks) != 1 {
+		t.Fatalf("len(hunks) = %d, want 1 (unchanged configMapB should be omitted)", len(hunks))
+	}
+	if hunks[0].NewText != configMapAChanged {
+		t.Errorf("hunk NewText = %q, want %q", hunks[0].NewText, configMapAChanged)
+	}
+}
+
+func TestHunksAdditionAndRemoval(t *testing.T) {
+	old := configMapA
+	new := configMapB
+
+	hunks := Hunks(old, new)
+	if len(hunks) != 2 {
+		t.Fatalf("len(hunks) = %d, want 2 (one removal, one addition)", len(hunks))
+	}
+	for _, h := range hunks {
+		if h.OldText == "" && h.NewText == "" {
+			t.Errorf("hunk %q has neither OldText nor NewText set", h.Key)
+		}
+	}
+}
+
+func TestRebuildKeepsRejectedHunksAsOld(t *testing.T) {
+	old := configMapA + "\n---\n" + configMapB
+	new := configMapAChanged + "\n---\n" + configMapB
+
+	hunks := Hunks(old, new)
+	accepted := map[string]bool{hunks[0].Key: false}
+
+	rebuilt := Rebuild(old, new, accepted)
+	if rebuilt != old {
+		t.Errorf("Rebuild() with a rejected hunk = %q, want the old content %q unchanged", rebuilt, old)
+	}
+}
+
+func TestRebuildAppliesAcceptedHunks(t *testing.T) {
+	old := configMapA + "\n---\n" + configMapB
+	new := configMapAChanged + "\n---\n" + configMapB
+
+	hunks := Hunks(old, new)
+	accepted := map[string]bool{hunks[0].Key: true}
+
+	rebuilt := Rebuild(old, new, accepted)
+	if rebuilt != new {
+		t.Errorf("Rebuild() with an accepted hunk = %q, want the new content %q", rebuilt, new)
+	}
+}
+
+func TestRebuildDropsRejectedAddition(t *testing.T) {
+	old := configMapA
+	new := configMapA + "\n---\n" + configMapB
+
+	hunks := Hunks(old, new)
+	accepted := map[string]bool{hunks[0].Key: false}
+
+	rebuilt := Rebuild(old, new, accepted)
+	if rebuilt != configMapA {
+		t.Errorf("Rebuild() with a rejected addition = %q, want just %q", rebuilt, configMapA)
+	}
+}
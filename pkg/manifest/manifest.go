@@ -0,0 +1,235 @@
+// Package manifest implements the declarative `chartsnap.yaml` test-suite
+// format: a single file describing many charts and many test cases so a
+// monorepo can run `chartsnap -m chartsnap.yaml` instead of invoking
+// chartsnap once per chart.
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cosmo-workspace/controller-testtools/pkg/charts"
+	"github.com/cosmo-workspace/controller-testtools/pkg/testcase"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the top level schema of a `chartsnap.yaml` file.
+type Manifest struct {
+	// Bases are other manifest files to load first. Entries in this
+	// manifest are appended after the bases' entries, and Defaults
+	// overrides the bases' Defaults field by field (DynamicFields are
+	// appended to the bases' instead).
+	Bases []string `yaml:"bases,omitempty"`
+	// Include is an alias for Bases kept for parity with tools like
+	// Tanka's chartfile.
+	Include []string `yaml:"include,omitempty"`
+	// Defaults are applied to every chart entry that doesn't set the
+	// corresponding field itself.
+	Defaults Defaults `yaml:"defaults,omitempty"`
+	// Charts is the list of charts to snapshot test.
+	Charts []ChartEntry `yaml:"charts"`
+}
+
+// Defaults holds manifest-wide settings that individual chart entries may
+// override.
+type Defaults struct {
+	HelmPath      string                  `yaml:"helmPath,omitempty"`
+	ReleaseName   string                  `yaml:"releaseName,omitempty"`
+	Namespace     string                  `yaml:"namespace,omitempty"`
+	DynamicFields []testcase.DynamicField `yaml:"dynamicFields,omitempty"`
+	// SkipCRDs disables rendering of a chart's crds/ directory. A chart
+	// entry that sets its own SkipCRDs to true wins regardless of this
+	// default; there's no way to force CRDs back on for a single entry
+	// once a manifest-wide default skips them.
+	SkipCRDs bool `yaml:"skipCRDs,omitempty"`
+}
+
+// ChartEntry describes a single chart and the test cases to run against it.
+type ChartEntry struct {
+	// Name is a human readable identifier for the entry. It is not passed
+	// on to charts.Snap; logs and reports identify the entry by Path or
+	// Repo.
+	Name string `yaml:"name,omitempty"`
+	// Path is a local chart directory. Mutually exclusive with Repo.
+	Path string `yaml:"path,omitempty"`
+	// Repo is a chart reference such as `myrepo/mychart` or
+	// `oci://.../mychart`. Mutually exclusive with Path.
+	Repo    string `yaml:"repo,omitempty"`
+	Version string `yaml:"version,omitempty"`
+
+	ReleaseName string `yaml:"releaseName,omitempty"`
+	Namespace   string `yaml:"namespace,omitempty"`
+	// SkipCRDs overrides Defaults.SkipCRDs for this entry when true.
+	SkipCRDs bool `yaml:"skipCRDs,omitempty"`
+
+	// ValuesFiles lists the test case values files to snapshot this chart
+	// with. If empty, the chart is snapshotted once with default values.
+	ValuesFiles []string `yaml:"valuesFiles,omitempty"`
+
+	TestSpec       testcase.TestSpec `yaml:"testSpec,omitempty"`
+	AdditionalArgs []string          `yaml:"additionalArgs,omitempty"`
+
+	ChartPullOptions charts.ChartPullOptions `yaml:"-"`
+}
+
+// Chart returns the chart path or reference this entry points at.
+func (c ChartEntry) Chart() string {
+	if c.Path != "" {
+		return c.Path
+	}
+	return c.Repo
+}
+
+// Load reads the manifest file at path, recursively merging in any
+// Bases/Include manifests it references.
+func Load(path string) (*Manifest, error) {
+	return load(path, map[string]bool{})
+}
+
+// load is Load's recursive implementation. ancestors holds the absolute
+// path of every manifest currently being loaded on the way down to path, so
+// a manifest that (directly or transitively) lists itself as a base is
+// reported as an error instead of recursing until the stack overflows.
+func load(path string, ancestors map[string]bool) (*Manifest, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve manifest path '%s': %w", path, err)
+	}
+	if ancestors[abs] {
+		return nil, fmt.Errorf("circular base manifest: '%s'", path)
+	}
+	ancestors[abs] = true
+	defer delete(ancestors, abs)
+
+	m, err := loadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(path)
+	rebaseChartEntries(m.Charts, dir)
+
+	merged := &Manifest{}
+	for _, base := range append(append([]string{}, m.Bases...), m.Include...) {
+		baseManifest, err := load(filepath.Join(dir, base), ancestors)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load base manifest '%s': %w", base, err)
+		}
+		merged.Charts = append(merged.Charts, baseManifest.Charts...)
+		merged.Defaults = mergeDefaults(merged.Defaults, baseManifest.Defaults)
+	}
+	merged.Charts = append(merged.Charts, m.Charts...)
+	merged.Defaults = mergeDefaults(merged.Defaults, m.Defaults)
+
+	return merged, nil
+}
+
+// rebaseChartEntries rewrites each entry's local-filesystem fields (Path,
+// ValuesFiles, and Repo when it turns out to be a local directory rather
+// than a repository/OCI reference) so they resolve relative to dir, the
+// directory the manifest that declared them lives in, the same way Bases
+// and Include already do. Without this, a manifest included from another
+// directory (or loaded via `-m` from outside its own directory) would
+// resolve its charts and values files against the process's CWD instead.
+func rebaseChartEntries(entries []ChartEntry, dir string) {
+	for i := range entries {
+		entries[i].Path = rebaseLocalPath(dir, entries[i].Path)
+		for j, v := range entries[i].ValuesFiles {
+			entries[i].ValuesFiles[j] = rebaseLocalPath(dir, v)
+		}
+		if repo := entries[i].Repo; repo != "" && !strings.Contains(repo, "://") {
+			if candidate := filepath.Join(dir, repo); dirExists(candidate) {
+				entries[i].Repo = candidate
+			}
+		}
+	}
+}
+
+// rebaseLocalPath joins dir onto p unless p is empty or already absolute.
+func rebaseLocalPath(dir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func loadFile(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest '%s': %w", path, err)
+	}
+	m := &Manifest{}
+	if err := yaml.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest '%s': %w", path, err)
+	}
+	return m, nil
+}
+
+// mergeDefaults overrides base field by field with any non-zero fields set
+// in override, except DynamicFields, which are appended to base's.
+func mergeDefaults(base, override Defaults) Defaults {
+	if override.SkipCRDs {
+		base.SkipCRDs = true
+	}
+	if override.HelmPath != "" {
+		base.HelmPath = override.HelmPath
+	}
+	if override.ReleaseName != "" {
+		base.ReleaseName = override.ReleaseName
+	}
+	if override.Namespace != "" {
+		base.Namespace = override.Namespace
+	}
+	if len(override.DynamicFields) > 0 {
+		base.DynamicFields = append(base.DynamicFields, override.DynamicFields...)
+	}
+	return base
+}
+
+// HelmTemplateCmdOptions expands the manifest into one HelmTemplateCmdOptions
+// per chart/values-file combination, ready to be handed to charts.Snap.
+func (m *Manifest) HelmTemplateCmdOptions() []charts.HelmTemplateCmdOptions {
+	var opts []charts.HelmTemplateCmdOptions
+	for _, c := range m.Charts {
+		valuesFiles := c.ValuesFiles
+		if len(valuesFiles) == 0 {
+			valuesFiles = []string{""}
+		}
+		dynamicFields := append(append([]testcase.DynamicField{}, m.Defaults.DynamicFields...), c.TestSpec.DynamicFields...)
+		for _, v := range valuesFiles {
+			opts = append(opts, charts.HelmTemplateCmdOptions{
+				HelmPath:         m.Defaults.HelmPath,
+				ReleaseName:      firstNonEmpty(c.ReleaseName, m.Defaults.ReleaseName, "testrelease"),
+				Namespace:        firstNonEmpty(c.Namespace, m.Defaults.Namespace, "testns"),
+				Chart:            c.Chart(),
+				ValuesFile:       v,
+				AdditionalArgs:   c.AdditionalArgs,
+				SkipCRDs:         c.SkipCRDs || m.Defaults.SkipCRDs,
+				DynamicFields:    dynamicFields,
+				ChartPullOptions: c.chartPullOptions(),
+			})
+		}
+	}
+	return opts
+}
+
+func (c ChartEntry) chartPullOptions() charts.ChartPullOptions {
+	o := c.ChartPullOptions
+	o.Version = firstNonEmpty(o.Version, c.Version)
+	return o
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
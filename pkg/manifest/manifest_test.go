@@ -0,0 +1,107 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRebasesLocalPaths(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	writeFile(t, filepath.Join(sub, "chartsnap.yaml"), `
+charts:
+  - path: mychart
+    valuesFiles:
+      - values/a.yaml
+      - /abs/values/b.yaml
+`)
+
+	m, err := Load(filepath.Join(sub, "chartsnap.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Charts[0].Path, filepath.Join(sub, "mychart"); got != want {
+		t.Errorf("Path = %q, want %q", got, want)
+	}
+	if got, want := m.Charts[0].ValuesFiles[0], filepath.Join(sub, "values/a.yaml"); got != want {
+		t.Errorf("ValuesFiles[0] = %q, want %q", got, want)
+	}
+	if got, want := m.Charts[0].ValuesFiles[1], "/abs/values/b.yaml"; got != want {
+		t.Errorf("ValuesFiles[1] = %q, want %q (absolute paths must pass through untouched)", got, want)
+	}
+}
+
+func TestLoadRebasesLocalRepoButNotRemoteRef(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "charts", "mychart", "Chart.yaml"), "name: mychart\n")
+	writeFile(t, filepath.Join(dir, "chartsnap.yaml"), `
+charts:
+  - repo: charts/mychart
+  - repo: bitnami/redis
+  - repo: oci://registry.example.com/mychart
+`)
+
+	m, err := Load(filepath.Join(dir, "chartsnap.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Charts[0].Repo, filepath.Join(dir, "charts/mychart"); got != want {
+		t.Errorf("local Repo = %q, want %q", got, want)
+	}
+	if got, want := m.Charts[1].Repo, "bitnami/redis"; got != want {
+		t.Errorf("repository reference Repo = %q, want unchanged %q", got, want)
+	}
+	if got, want := m.Charts[2].Repo, "oci://registry.example.com/mychart"; got != want {
+		t.Errorf("OCI Repo = %q, want unchanged %q", got, want)
+	}
+}
+
+func TestLoadDetectsCircularBases(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "bases: [b.yaml]\ncharts: []\n")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "bases: [a.yaml]\ncharts: []\n")
+
+	if _, err := Load(filepath.Join(dir, "a.yaml")); err == nil {
+		t.Fatal("expected an error for circular bases, got nil")
+	}
+}
+
+func TestLoadAllowsDiamondBases(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "base.yaml"), "charts:\n  - path: shared\n")
+	writeFile(t, filepath.Join(dir, "a.yaml"), "bases: [base.yaml]\ncharts: []\n")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "bases: [base.yaml]\ncharts: []\n")
+	writeFile(t, filepath.Join(dir, "top.yaml"), "bases: [a.yaml, b.yaml]\ncharts: []\n")
+
+	m, err := Load(filepath.Join(dir, "top.yaml"))
+	if err != nil {
+		t.Fatalf("diamond-shaped bases should load without error: %v", err)
+	}
+	if len(m.Charts) != 2 {
+		t.Errorf("len(Charts) = %d, want 2 (base loaded once per inclusion path)", len(m.Charts))
+	}
+}
+
+func TestMergeDefaults(t *testing.T) {
+	base := Defaults{HelmPath: "helm", Namespace: "base-ns"}
+	override := Defaults{Namespace: "override-ns"}
+
+	got := mergeDefaults(base, override)
+	if got.HelmPath != "helm" {
+		t.Errorf("HelmPath = %q, want unchanged %q", got.HelmPath, "helm")
+	}
+	if got.Namespace != "override-ns" {
+		t.Errorf("Namespace = %q, want %q", got.Namespace, "override-ns")
+	}
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JSONReporter writes a single JSON document with every test case to path,
+// for dashboards or scripts that don't want to parse JUnit XML.
+type JSONReporter struct {
+	path string
+
+	mu    sync.Mutex
+	cases []jsonTestCase
+}
+
+type jsonTestCase struct {
+	Chart          string  `json:"chart"`
+	Name           string  `json:"name"`
+	Status         string  `json:"status"`
+	FailureMessage string  `json:"failureMessage,omitempty"`
+	DurationSecs   float64 `json:"durationSeconds"`
+}
+
+// NewJSONReporter returns a Reporter that writes JSON to path on Finish.
+func NewJSONReporter(path string) *JSONReporter {
+	return &JSONReporter{path: path}
+}
+
+func (r *JSONReporter) Start() {}
+
+func (r *JSONReporter) Case(chart, name string, status Status, failureMessage string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cases = append(r.cases, jsonTestCase{
+		Chart:          chart,
+		Name:           name,
+		Status:         status.String(),
+		FailureMessage: failureMessage,
+		DurationSecs:   duration.Seconds(),
+	})
+}
+
+func (r *JSONReporter) Finish() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.cases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal json report: %w", err)
+	}
+	if err := os.WriteFile(r.path, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write json report to '%s': %w", r.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JUnitReporter writes one <testsuite> per chart and one <testcase> per
+// values file to path, so CI systems like Jenkins, GitHub Actions, and
+// GitLab can render chartsnap results natively.
+type JUnitReporter struct {
+	path string
+
+	mu    sync.Mutex
+	suite map[string]*junitTestSuite
+	order []string
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name         `xml:"testsuite"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	XMLName xml.Name      `xml:"testcase"`
+	Name    string        `xml:"name,attr"`
+	Time    float64       `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",cdata"`
+}
+
+// NewJUnitReporter returns a Reporter that writes JUnit XML to path on Finish.
+func NewJUnitReporter(path string) *JUnitReporter {
+	return &JUnitReporter{path: path, suite: map[string]*junitTestSuite{}}
+}
+
+func (r *JUnitReporter) Start() {}
+
+func (r *JUnitReporter) Case(chart, name string, status Status, failureMessage string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.suite[chart]
+	if !ok {
+		s = &junitTestSuite{Name: chart}
+		r.suite[chart] = s
+		r.order = append(r.order, chart)
+	}
+
+	tc := &junitTestCase{Name: name, Time: duration.Seconds()}
+	if status != Pass {
+		tc.Failure = &junitFailure{Message: status.String(), Body: failureMessage}
+		s.Failures++
+	}
+	s.Tests++
+	s.Cases = append(s.Cases, tc)
+}
+
+func (r *JUnitReporter) Finish() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := &junitTestSuites{}
+	for _, chart := range r.order {
+		out.Suites = append(out.Suites, r.suite[chart])
+	}
+
+	b, err := xml.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal junit report: %w", err)
+	}
+	b = append([]byte(xml.Header), b...)
+
+	if err := os.WriteFile(r.path, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write junit report to '%s': %w", r.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package report turns chartsnap test results into CI-friendly output such
+// as JUnit XML or JSON, in addition to the default colored TTY banners.
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status is the outcome of a single snapshot test case.
+type Status int
+
+const (
+	Pass Status = iota
+	Fail
+	Error
+)
+
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "pass"
+	case Fail:
+		return "fail"
+	case Error:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Reporter receives events for a chartsnap run and renders them in some
+// format. Start is called once before any cases run, Case once per
+// chart+values-file combination, and Finish once after every case has been
+// reported.
+type Reporter interface {
+	Start()
+	Case(chart, name string, status Status, failureMessage string, duration time.Duration)
+	Finish() error
+}
+
+// Parse builds the Reporter described by spec, which has the form
+// 'format=path', e.g. 'junit=out.xml' or 'json=out.json'.
+func Parse(spec string) (Reporter, error) {
+	format, path, ok := strings.Cut(spec, "=")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid --report value '%s', expected 'format=path'", spec)
+	}
+	switch format {
+	case "junit":
+		return NewJUnitReporter(path), nil
+	case "json":
+		return NewJSONReporter(path), nil
+	default:
+		return nil, fmt.Errorf("unknown report format '%s', supported formats are 'junit' and 'json'", format)
+	}
+}
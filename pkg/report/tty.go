@@ -0,0 +1,55 @@
+package report
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// TTYReporter prints the colored PASS/FAIL banners chartsnap has always
+// printed; the RUNS banner is printed by RunningPrintln. It is always
+// included alongside any --report reporters.
+type TTYReporter struct {
+	mu     sync.Mutex
+	failed int
+}
+
+// NewTTYReporter returns the default banner reporter.
+func NewTTYReporter() *TTYReporter {
+	return &TTYReporter{}
+}
+
+func (r *TTYReporter) Start() {}
+
+func (r *TTYReporter) Case(chart, name string, status Status, failureMessage string, duration time.Duration) {
+	if status != Pass {
+		r.mu.Lock()
+		r.failed++
+		r.mu.Unlock()
+		r.bannerPrintln("FAIL", failureMessage, color.FgRed, color.BgRed)
+	}
+}
+
+func (r *TTYReporter) Finish() error {
+	r.mu.Lock()
+	failed := r.failed
+	r.mu.Unlock()
+	if failed == 0 {
+		r.bannerPrintln("PASS", "Snapshot matched", color.FgGreen, color.BgGreen)
+	}
+	return nil
+}
+
+func (r *TTYReporter) bannerPrintln(banner string, message string, fgColor color.Attribute, bgColor color.Attribute) {
+	color.New(color.FgWhite, bgColor).Printf(" %s ", banner)
+	color.New(fgColor).Printf(" %s\n", message)
+}
+
+// RunningPrintln prints the "RUNS" banner emitted before a case starts. It
+// isn't part of the Reporter interface since it fires before a case's
+// outcome is known.
+func RunningPrintln(message string) {
+	color.New(color.FgWhite, color.BgBlue).Printf(" %s ", "RUNS")
+	color.New(color.Attribute(0)).Printf(" %s\n", message)
+}
@@ -0,0 +1,114 @@
+// Package review implements the --interactive accept/reject/skip/quit
+// snapshot review prompt, in the same spirit as Jest's interactive
+// --updateSnapshot mode.
+package review
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cosmo-workspace/controller-testtools/pkg/charts"
+	"github.com/cosmo-workspace/controller-testtools/pkg/diff"
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// ErrQuit is returned by Run when the user chose [q]uit.
+var ErrQuit = fmt.Errorf("review aborted by user")
+
+// Available reports whether an --interactive review prompt can be shown,
+// i.e. stdout is a terminal.
+func Available() bool {
+	return isatty.IsTerminal(os.Stdout.Fd())
+}
+
+// Run walks result's hunks one at a time, printing a colored diff and
+// prompting [a]ccept/[r]eject/[s]kip/[q]uit, then writes any accepted
+// hunks back into the snapshot file. It returns whether the snapshot was
+// left matching after the review.
+func Run(result *charts.Result) (matched bool, err error) {
+	return run(result, os.Stdout, bufio.NewReader(os.Stdin))
+}
+
+func run(result *charts.Result, w io.Writer, in *bufio.Reader) (bool, error) {
+	hunks := diff.Hunks(result.Stored, result.Rendered)
+	if len(hunks) == 0 {
+		return true, nil
+	}
+
+	accepted := map[string]bool{}
+	// quit writes whatever has been decided so far before bailing out, so
+	// [q]uit (or stdin closing) behaves like git add -p/Jest and preserves
+	// earlier [a]ccept/[r]eject decisions instead of discarding the whole
+	// session.
+	quit := func() (bool, error) {
+		if _, err := writeAccepted(result, accepted); err != nil {
+			return false, err
+		}
+		return false, ErrQuit
+	}
+
+	for _, h := range hunks {
+		fmt.Fprintf(w, "\n%s\n", color.YellowString("--- %s ---", h.Key))
+		printColoredDiff(w, h.Unified)
+
+		for {
+			fmt.Fprint(w, "[a]ccept / [r]eject / [s]kip / [q]uit? ")
+			line, readErr := in.ReadString('\n')
+			if readErr != nil && readErr != io.EOF {
+				return false, readErr
+			}
+			if readErr == io.EOF && strings.TrimSpace(line) == "" {
+				// Stdin closed with nothing left to read; treat it the same
+				// as [q]uit instead of looping on an exhausted reader.
+				return quit()
+			}
+			switch strings.ToLower(strings.TrimSpace(line)) {
+			case "a", "accept":
+				accepted[h.Key] = true
+			case "r", "reject":
+				accepted[h.Key] = false
+			case "s", "skip":
+				// leave undecided for this run; treated the same as reject
+				// when rebuilding since the snapshot can't record "deferred"
+			case "q", "quit":
+				return quit()
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	rebuilt, err := writeAccepted(result, accepted)
+	if err != nil {
+		return false, err
+	}
+	return rebuilt == result.Rendered, nil
+}
+
+// writeAccepted rebuilds the snapshot from result's hunks and accepted, and
+// writes it back to result's snapshot file.
+func writeAccepted(result *charts.Result, accepted map[string]bool) (string, error) {
+	rebuilt := diff.Rebuild(result.Stored, result.Rendered, accepted)
+	if err := charts.WriteSnapshot(result, rebuilt); err != nil {
+		return "", fmt.Errorf("failed to write reviewed snapshot: %w", err)
+	}
+	return rebuilt, nil
+}
+
+func printColoredDiff(w io.Writer, unified string) {
+	for _, line := range strings.Split(unified, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++"):
+			fmt.Fprintln(w, color.GreenString(line))
+		case strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---"):
+			fmt.Fprintln(w, color.RedString(line))
+		default:
+			fmt.Fprintln(w, line)
+		}
+	}
+}
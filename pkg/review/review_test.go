@@ -0,0 +1,122 @@
+package review
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cosmo-workspace/controller-testtools/pkg/charts"
+)
+
+const configMapA = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: default
+data:
+  foo: bar
+`
+
+const configMapAChanged = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: default
+data:
+  foo: baz
+`
+
+const configMapB = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  namespace: default
+data:
+  foo: bar
+`
+
+const configMapBChanged = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  namespace: default
+data:
+  foo: qux
+`
+
+func newResult(t *testing.T, stored, rendered string) *charts.Result {
+	t.Helper()
+	return &charts.Result{
+		Stored:       stored,
+		Rendered:     rendered,
+		SnapshotFile: filepath.Join(t.TempDir(), "default.snap"),
+	}
+}
+
+func TestRunAcceptAll(t *testing.T) {
+	result := newResult(t, configMapA+"\n---\n"+configMapB, configMapAChanged+"\n---\n"+configMapBChanged)
+
+	matched, err := run(result, &bytes.Buffer{}, bufio.NewReader(strings.NewReader("a\na\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matched {
+		t.Error("matched = false, want true after accepting every hunk")
+	}
+
+	got, err := os.ReadFile(result.SnapshotFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != result.Rendered {
+		t.Errorf("snapshot = %q, want the fully accepted rendering %q", got, result.Rendered)
+	}
+}
+
+func TestRunQuitWritesPriorAcceptDecisions(t *testing.T) {
+	result := newResult(t, configMapA+"\n---\n"+configMapB, configMapAChanged+"\n---\n"+configMapBChanged)
+
+	// Accept the first hunk, then quit before deciding the second.
+	matched, err := run(result, &bytes.Buffer{}, bufio.NewReader(strings.NewReader("a\nq\n")))
+	if !errors.Is(err, ErrQuit) {
+		t.Fatalf("err = %v, want ErrQuit", err)
+	}
+	if matched {
+		t.Error("matched = true, want false on quit")
+	}
+
+	got, err := os.ReadFile(result.SnapshotFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := configMapAChanged + "\n---\n" + configMapB
+	if string(got) != want {
+		t.Errorf("snapshot after quit = %q, want %q (the accepted first hunk kept, the undecided second hunk left as-is)", got, want)
+	}
+}
+
+func TestRunEOFWritesPriorAcceptDecisions(t *testing.T) {
+	result := newResult(t, configMapA+"\n---\n"+configMapB, configMapAChanged+"\n---\n"+configMapBChanged)
+
+	// Accept the first hunk, then stdin closes before the second is decided.
+	matched, err := run(result, &bytes.Buffer{}, bufio.NewReader(strings.NewReader("a\n")))
+	if !errors.Is(err, ErrQuit) {
+		t.Fatalf("err = %v, want ErrQuit", err)
+	}
+	if matched {
+		t.Error("matched = true, want false on EOF")
+	}
+
+	got, err := os.ReadFile(result.SnapshotFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := configMapAChanged + "\n---\n" + configMapB
+	if string(got) != want {
+		t.Errorf("snapshot after EOF = %q, want %q", got, want)
+	}
+}
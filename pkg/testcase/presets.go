@@ -0,0 +1,88 @@
+package testcase
+
+// presets is a small built-in library of DynamicField matchers for values
+// that show up in most charts, so users don't have to hand-write a
+// valueRegex/jsonPath for things every chart has.
+var presets = map[string]DynamicField{
+	// kubernetesCABundle matches generated CA bundles/certs in Secrets,
+	// e.g. webhook certificates.
+	"kubernetesCABundle": {
+		Kind:        "Secret",
+		All:         true,
+		JSONPath:    []string{"/data/ca.crt", "/data/tls.crt", "/data/tls.key"},
+		Replacement: "<CA_BUNDLE>",
+	},
+	// helmHookHash matches the 'helm.sh/hook-weight'-adjacent content hash
+	// annotations Helm stamps onto hook resources.
+	"helmHookHash": {
+		All:         true,
+		JSONPath:    []string{"/metadata/annotations/helm.sh~1hook-hash"},
+		Replacement: "<HOOK_HASH>",
+	},
+	// randAlphaNum matches Secret data generated by Helm's randAlphaNum/
+	// randAlpha/randNumeric/randAscii template functions. It sets no
+	// JSONPath, so on its own it redacts nothing; the dynamicField using it
+	// lists the jsonPath entries to redact.
+	"randAlphaNum": {
+		Kind:        "Secret",
+		All:         true,
+		ValueRegex:  "^[A-Za-z0-9]+$",
+		Replacement: "<RANDOM>",
+	},
+}
+
+// ResolvePreset expands f.Preset (if set) into its built-in DynamicField,
+// with any fields f itself sets overriding the preset's defaults.
+func ResolvePreset(f DynamicField) (DynamicField, bool) {
+	if f.Preset == "" {
+		return f, true
+	}
+	base, ok := presets[f.Preset]
+	if !ok {
+		return f, false
+	}
+	return mergeDynamicField(base, f), true
+}
+
+// mergeDynamicField overrides base field by field with any non-zero fields
+// set in override, except Preset which is dropped once resolved.
+func mergeDynamicField(base, override DynamicField) DynamicField {
+	merged := base
+	merged.Preset = ""
+	if override.APIVersion != "" {
+		merged.APIVersion = override.APIVersion
+	}
+	if override.Kind != "" {
+		merged.Kind = override.Kind
+	}
+	// Name/NameRegex/NameGlob/All are mutually exclusive selectors (see the
+	// DynamicField doc comment), so an override that sets any of them
+	// replaces the base's selector entirely instead of being layered on
+	// top of it. Otherwise a preset's `all: true` (or a preset's Name)
+	// could never be narrowed down by an override.
+	if override.Name != "" || override.NameRegex != "" || override.NameGlob != "" || override.All {
+		merged.Name = override.Name
+		merged.NameRegex = override.NameRegex
+		merged.NameGlob = override.NameGlob
+		merged.All = override.All
+	}
+	if override.Namespace != "" {
+		merged.Namespace = override.Namespace
+	}
+	if override.NamespaceRegex != "" {
+		merged.NamespaceRegex = override.NamespaceRegex
+	}
+	if override.NamespaceGlob != "" {
+		merged.NamespaceGlob = override.NamespaceGlob
+	}
+	if len(override.JSONPath) > 0 {
+		merged.JSONPath = override.JSONPath
+	}
+	if override.ValueRegex != "" {
+		merged.ValueRegex = override.ValueRegex
+	}
+	if override.Replacement != "" {
+		merged.Replacement = override.Replacement
+	}
+	return merged
+}
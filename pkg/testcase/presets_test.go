@@ -0,0 +1,43 @@
+package testcase
+
+import "testing"
+
+func TestResolvePresetOverrideNarrowsAllToName(t *testing.T) {
+	f := DynamicField{Preset: "randAlphaNum", Name: "myapp-secret"}
+
+	got, ok := ResolvePreset(f)
+	if !ok {
+		t.Fatal("expected preset to resolve")
+	}
+	if got.All {
+		t.Error("override setting Name should clear the preset's All, not layer on top of it")
+	}
+	if got.Name != "myapp-secret" {
+		t.Errorf("Name = %q, want %q", got.Name, "myapp-secret")
+	}
+	if got.Kind != "Secret" {
+		t.Errorf("Kind = %q, want preset's %q to survive", got.Kind, "Secret")
+	}
+}
+
+func TestResolvePresetOverrideKeepsAllWhenUnset(t *testing.T) {
+	f := DynamicField{Preset: "kubernetesCABundle", Namespace: "prod"}
+
+	got, ok := ResolvePreset(f)
+	if !ok {
+		t.Fatal("expected preset to resolve")
+	}
+	if !got.All {
+		t.Error("an override that doesn't touch the selector should leave the preset's All alone")
+	}
+	if got.Namespace != "prod" {
+		t.Errorf("Namespace = %q, want %q", got.Namespace, "prod")
+	}
+}
+
+func TestResolvePresetUnknownPreset(t *testing.T) {
+	_, ok := ResolvePreset(DynamicField{Preset: "nope"})
+	if ok {
+		t.Error("expected an unknown preset to report ok=false")
+	}
+}
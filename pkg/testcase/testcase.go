@@ -0,0 +1,57 @@
+// Package testcase defines the `testSpec` property that can be embedded in a
+// chart's values file to describe and control a chartsnap test case.
+package testcase
+
+// TestSpec is the `testSpec` property of a values file.
+type TestSpec struct {
+	// Desc is a description for the set of values.
+	Desc string `yaml:"desc,omitempty"`
+	// DynamicFields defines values that are dynamically generated by Helm
+	// functions like 'randAlphaNum'. They are replaced with a placeholder before
+	// comparing against the snapshot so that the snapshot does not flap
+	// between runs.
+	DynamicFields []DynamicField `yaml:"dynamicFields,omitempty"`
+}
+
+// DynamicField selects one or more fields in rendered resources that should
+// be redacted before snapshotting. A field matches a resource when
+// APIVersion/Kind match exactly (when set) and the resource name matches
+// according to Name/NameRegex/NameGlob/All.
+type DynamicField struct {
+	// Preset expands to one of the built-in matchers in presets.go (e.g.
+	// 'kubernetesCABundle', 'helmHookHash', 'randAlphaNum'). Any other
+	// field set alongside Preset is merged on top of the preset's values.
+	Preset string `yaml:"preset,omitempty"`
+
+	APIVersion string `yaml:"apiVersion,omitempty"`
+	Kind       string `yaml:"kind,omitempty"`
+
+	// Name matches a resource name exactly. NameRegex/NameGlob match it as
+	// a regular expression or a shell glob respectively. All matches every
+	// resource of Kind regardless of name. Exactly one of these should be
+	// set; if more than one is, All wins, then Name, NameRegex and NameGlob
+	// in that order.
+	Name      string `yaml:"name,omitempty"`
+	NameRegex string `yaml:"nameRegex,omitempty"`
+	NameGlob  string `yaml:"nameGlob,omitempty"`
+	All       bool   `yaml:"all,omitempty"`
+
+	// Namespace/NamespaceRegex/NamespaceGlob further restrict the match to
+	// resources in a given namespace. Empty means any namespace.
+	Namespace      string `yaml:"namespace,omitempty"`
+	NamespaceRegex string `yaml:"namespaceRegex,omitempty"`
+	NamespaceGlob  string `yaml:"namespaceGlob,omitempty"`
+
+	// JSONPath lists the slash-separated field paths to redact, e.g.
+	// '/data/COOKIE_HASHKEY'.
+	JSONPath []string `yaml:"jsonPath,omitempty"`
+
+	// ValueRegex, if set, only redacts a field when its current value
+	// matches, so values a user explicitly set in their test case are left
+	// alone.
+	ValueRegex string `yaml:"valueRegex,omitempty"`
+
+	// Replacement substitutes a stable placeholder for the field instead of
+	// deleting it. Defaults to "<REDACTED>".
+	Replacement string `yaml:"replacement,omitempty"`
+}